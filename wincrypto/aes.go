@@ -0,0 +1,138 @@
+package wincrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// reference:
+// https://learn.microsoft.com/en-us/windows/win32/seccrypto/simple-blob
+// https://learn.microsoft.com/en-us/windows/win32/seccrypto/plaintext-key-blob
+
+const (
+	simpleBlob       = 0x01
+	plainTextKeyBlob = 0x08
+
+	cAlgAES128 = 0x0000660E
+	cAlgAES192 = 0x0000660F
+	cAlgAES256 = 0x00006610
+)
+
+// ExportAESKeyPlaintextBlob is used to export an AES key with PlainTextKeyBlob,
+// as consumed by CryptImportKey without a wrapping key.
+func ExportAESKeyPlaintextBlob(key []byte) ([]byte, error) {
+	algID, err := aesAlgIDFromKeyLen(len(key))
+	if err != nil {
+		return nil, err
+	}
+	buffer := bytes.NewBuffer(make([]byte, 0, 12+len(key)))
+	// write blob header
+	buffer.WriteByte(plainTextKeyBlob)
+	buffer.WriteByte(curBlobVersion)
+	buffer.Write([]byte{0x00, 0x00}) // reserved
+	_ = binary.Write(buffer, binary.LittleEndian, algID)
+	// write key length and raw key
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(key)))
+	buffer.Write(key)
+	return buffer.Bytes(), nil
+}
+
+// ExportAESKeySimpleBlob is used to export an AES key with SimpleBlob, the
+// session key is encrypted with the PKCS#1 v1.5 padding using pub, as
+// consumed by CryptImportKey with a CALG_RSA_KEYX wrapping key.
+func ExportAESKeySimpleBlob(key []byte, pub *rsa.PublicKey, algID uint32) ([]byte, error) {
+	switch algID {
+	case cAlgAES128, cAlgAES192, cAlgAES256:
+	default:
+		return nil, errors.New("invalid aes algorithm id")
+	}
+	wantAlgID, err := aesAlgIDFromKeyLen(len(key))
+	if err != nil {
+		return nil, err
+	}
+	if wantAlgID != algID {
+		return nil, errors.New("aes key length does not match algorithm id")
+	}
+	encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, pub, reverseBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	buffer := bytes.NewBuffer(make([]byte, 0, 8+4+len(encrypted)))
+	// write blob header
+	buffer.WriteByte(simpleBlob)
+	buffer.WriteByte(curBlobVersion)
+	buffer.Write([]byte{0x00, 0x00}) // reserved
+	_ = binary.Write(buffer, binary.LittleEndian, algID)
+	// write the algorithm used to wrap the session key
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(cAlgRSAKeyX))
+	// write the RSA-encrypted session key in little-endian order
+	buffer.Write(reverseBytes(encrypted))
+	return buffer.Bytes(), nil
+}
+
+// ImportAESKeyPlaintextBlob is used to import an AES key from PlainTextKeyBlob.
+func ImportAESKeyPlaintextBlob(blob []byte) ([]byte, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readBlobHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if header.bType != plainTextKeyBlob {
+		return nil, errors.New("invalid plaintext key blob type")
+	}
+	var keyLen uint32
+	if err := binary.Read(reader, binary.LittleEndian, &keyLen); err != nil {
+		return nil, errors.New("failed to read key length")
+	}
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(reader, key); err != nil {
+		return nil, errors.New("failed to read key")
+	}
+	return key, nil
+}
+
+// ImportAESKeySimpleBlob is used to import an AES key from SimpleBlob, the
+// session key is decrypted with priv.
+func ImportAESKeySimpleBlob(blob []byte, priv *rsa.PrivateKey) ([]byte, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readBlobHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if header.bType != simpleBlob {
+		return nil, errors.New("invalid simple blob type")
+	}
+	var wrapAlgID uint32
+	if err := binary.Read(reader, binary.LittleEndian, &wrapAlgID); err != nil {
+		return nil, errors.New("failed to read wrapping algorithm id")
+	}
+	if wrapAlgID != cAlgRSAKeyX {
+		return nil, errors.New("unsupported key wrapping algorithm")
+	}
+	encrypted := make([]byte, priv.Size())
+	if _, err := io.ReadFull(reader, encrypted); err != nil {
+		return nil, errors.New("failed to read wrapped session key")
+	}
+	key, err := rsa.DecryptPKCS1v15(rand.Reader, priv, reverseBytes(encrypted))
+	if err != nil {
+		return nil, err
+	}
+	return reverseBytes(key), nil
+}
+
+func aesAlgIDFromKeyLen(n int) (uint32, error) {
+	switch n {
+	case 16:
+		return cAlgAES128, nil
+	case 24:
+		return cAlgAES192, nil
+	case 32:
+		return cAlgAES256, nil
+	default:
+		return 0, errors.New("invalid aes key length")
+	}
+}
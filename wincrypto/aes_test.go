@@ -0,0 +1,113 @@
+package wincrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func TestExportImportAESKeyPlaintextBlob(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportAESKeyPlaintextBlob(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportAESKeyPlaintextBlob(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Fatal("imported key does not match the original")
+	}
+}
+
+func TestExportImportAESKeySimpleBlob(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keyLen := range []int{16, 24, 32} {
+		key := make([]byte, keyLen)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		algID, err := aesAlgIDFromKeyLen(keyLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob, err := ExportAESKeySimpleBlob(key, &priv.PublicKey, algID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ImportAESKeySimpleBlob(blob, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("imported key does not match the original for key length %d", keyLen)
+		}
+	}
+}
+
+func TestExportAESKeySimpleBlobLengthMismatch(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := make([]byte, 16)
+	if _, err := ExportAESKeySimpleBlob(key, &priv.PublicKey, cAlgAES256); err == nil {
+		t.Fatal("expected an error for a key length that does not match algID")
+	}
+}
+
+// TestExportAESKeySimpleBlobByteOrder pins the byte order used by
+// ExportAESKeySimpleBlob by unwrapping the SIMPLEBLOB with a raw RSA
+// decryption (plain modular exponentiation), independent of
+// ImportAESKeySimpleBlob. This guards against the two reverseBytes calls
+// (session key, then wrapped ciphertext) cancelling each other out and
+// silently producing a blob with the wrong byte order for real CryptoAPI.
+func TestExportAESKeySimpleBlobByteOrder(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportAESKeySimpleBlob(key, &priv.PublicKey, cAlgAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blobHeader(8) + wrapping algID(4) + wrapped session key(keySize)
+	wrapped := blob[12:]
+	if len(wrapped) != priv.Size() {
+		t.Fatalf("unexpected wrapped key length: %d", len(wrapped))
+	}
+	// the wrapped session key is stored little-endian, reverse it back to
+	// the big-endian form RSA operates on.
+	c := new(big.Int).SetBytes(reverseBytes(wrapped))
+	m := new(big.Int).Exp(c, priv.D, priv.N)
+	padded := make([]byte, priv.Size())
+	padded = m.FillBytes(padded)
+	// PKCS#1 v1.5: 0x00 0x02 <non-zero padding> 0x00 <data>
+	if padded[0] != 0x00 || padded[1] != 0x02 {
+		t.Fatalf("unexpected PKCS#1 v1.5 padding header: %x", padded[:2])
+	}
+	i := 2
+	for ; i < len(padded); i++ {
+		if padded[i] == 0x00 {
+			break
+		}
+	}
+	data := padded[i+1:]
+	// the AES key is embedded little-endian, matching the modulus encoding.
+	if !bytes.Equal(reverseBytes(data), key) {
+		t.Fatal("decrypted session key bytes do not match the expected little-endian layout")
+	}
+}
@@ -0,0 +1,196 @@
+package wincrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// about RSA encryption mode.
+const (
+	RSAModePKCS1v15 = 1
+	RSAModeOAEP     = 2
+)
+
+// sealedBlobMagic and sealedBlobVersion identify the framed output of
+// SealRSAAES, so OpenRSAAES can reject data it does not understand.
+const (
+	sealedBlobMagic   = 0x41524C53 // "SLRA"
+	sealedBlobVersion = 0x01
+
+	sealedAESKeySize = 32 // AES-256
+	sealedNonceSize  = 12 // GCM standard nonce size
+)
+
+// EncryptRSA encrypts plaintext with pub, chunking the input so that it can
+// exceed the single-block RSA capacity. mode selects RSAModePKCS1v15 or
+// RSAModeOAEP (SHA-256, no label). The output is the concatenation of
+// fixed-size key.Size() ciphertext blocks.
+func EncryptRSA(pub *rsa.PublicKey, plaintext []byte, mode int) ([]byte, error) {
+	return EncryptRSAOAEP(pub, plaintext, mode, nil)
+}
+
+// EncryptRSAOAEP is EncryptRSA with an optional OAEP label, the label is
+// ignored when mode is RSAModePKCS1v15.
+func EncryptRSAOAEP(pub *rsa.PublicKey, plaintext []byte, mode int, label []byte) ([]byte, error) {
+	chunkSize, err := rsaPlaintextChunkSize(pub.Size(), mode)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := pub.Size()
+	chunks := len(plaintext) / chunkSize
+	if len(plaintext)%chunkSize != 0 || len(plaintext) == 0 {
+		chunks++
+	}
+	output := make([]byte, 0, chunks*blockSize)
+	for i := 0; i < chunks; i++ {
+		start := i * chunkSize
+		end := start + chunkSize
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+		chunk := plaintext[start:end]
+		var block []byte
+		switch mode {
+		case RSAModePKCS1v15:
+			block, err = rsa.EncryptPKCS1v15(rand.Reader, pub, chunk)
+		case RSAModeOAEP:
+			block, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, chunk, label)
+		}
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, block...)
+	}
+	return output, nil
+}
+
+// DecryptRSA decrypts ciphertext produced by EncryptRSA/EncryptRSAOAEP,
+// splitting it back into key.Size() blocks before unwrapping each one.
+func DecryptRSA(priv *rsa.PrivateKey, ciphertext []byte, mode int) ([]byte, error) {
+	return DecryptRSAOAEP(priv, ciphertext, mode, nil)
+}
+
+// DecryptRSAOAEP is DecryptRSA with an optional OAEP label, the label is
+// ignored when mode is RSAModePKCS1v15.
+func DecryptRSAOAEP(priv *rsa.PrivateKey, ciphertext []byte, mode int, label []byte) ([]byte, error) {
+	if _, err := rsaPlaintextChunkSize(priv.Size(), mode); err != nil {
+		return nil, err
+	}
+	blockSize := priv.Size()
+	if len(ciphertext)%blockSize != 0 {
+		return nil, errors.New("invalid rsa ciphertext length")
+	}
+	var plaintext []byte
+	for i := 0; i < len(ciphertext); i += blockSize {
+		block := ciphertext[i : i+blockSize]
+		var chunk []byte
+		var err error
+		switch mode {
+		case RSAModePKCS1v15:
+			chunk, err = rsa.DecryptPKCS1v15(rand.Reader, priv, block)
+		case RSAModeOAEP:
+			chunk, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, block, label)
+		}
+		if err != nil {
+			return nil, err
+		}
+		plaintext = append(plaintext, chunk...)
+	}
+	return plaintext, nil
+}
+
+func rsaPlaintextChunkSize(keySize int, mode int) (int, error) {
+	switch mode {
+	case RSAModePKCS1v15:
+		return keySize - 11, nil
+	case RSAModeOAEP:
+		hashSize := sha256.Size
+		size := keySize - 2*hashSize - 2
+		if size <= 0 {
+			return 0, errors.New("rsa key too small for oaep")
+		}
+		return size, nil
+	default:
+		return 0, errors.New("invalid rsa encryption mode")
+	}
+}
+
+// SealRSAAES generates a random AES-256-GCM key, encrypts plaintext with it,
+// wraps the AES key with RSA-OAEP for pub, and returns a single framed blob
+// that OpenRSAAES can unwrap given the matching private key.
+func SealRSAAES(pub *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	key := make([]byte, sealedAESKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, sealedNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	gcm, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, 0, 4+1+4+len(wrappedKey)+len(nonce)+len(ciphertext))
+	buf4 := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf4, sealedBlobMagic)
+	buffer = append(buffer, buf4...)
+	buffer = append(buffer, sealedBlobVersion)
+	binary.LittleEndian.PutUint32(buf4, uint32(len(wrappedKey)))
+	buffer = append(buffer, buf4...)
+	buffer = append(buffer, wrappedKey...)
+	buffer = append(buffer, nonce...)
+	buffer = append(buffer, ciphertext...)
+	return buffer, nil
+}
+
+// OpenRSAAES reverses SealRSAAES using priv to unwrap the AES key.
+func OpenRSAAES(priv *rsa.PrivateKey, sealed []byte) ([]byte, error) {
+	if len(sealed) < 4+1+4+sealedNonceSize {
+		return nil, errors.New("sealed blob is too short")
+	}
+	if binary.LittleEndian.Uint32(sealed[0:4]) != sealedBlobMagic {
+		return nil, errors.New("invalid sealed blob magic")
+	}
+	if sealed[4] != sealedBlobVersion {
+		return nil, errors.New("unsupported sealed blob version")
+	}
+	offset := 5
+	wrappedKeyLen := int(binary.LittleEndian.Uint32(sealed[offset : offset+4]))
+	offset += 4
+	if len(sealed) < offset+wrappedKeyLen+sealedNonceSize {
+		return nil, errors.New("sealed blob is truncated")
+	}
+	wrappedKey := sealed[offset : offset+wrappedKeyLen]
+	offset += wrappedKeyLen
+	nonce := sealed[offset : offset+sealedNonceSize]
+	offset += sealedNonceSize
+	ciphertext := sealed[offset:]
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, wrappedKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,115 @@
+package wincrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func testRSAEnvelopeKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEncryptDecryptRSAPKCS1v15(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	chunkSize, err := rsaPlaintextChunkSize(key.Size(), RSAModePKCS1v15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, chunkSize * 3} {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := EncryptRSA(&key.PublicKey, plaintext, RSAModePKCS1v15)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		decrypted, err := DecryptRSA(key, ciphertext, RSAModePKCS1v15)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestEncryptDecryptRSAOAEP(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	chunkSize, err := rsaPlaintextChunkSize(key.Size(), RSAModeOAEP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := []byte("grt-config")
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, chunkSize * 3} {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := EncryptRSAOAEP(&key.PublicKey, plaintext, RSAModeOAEP, label)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		decrypted, err := DecryptRSAOAEP(key, ciphertext, RSAModeOAEP, label)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestDecryptRSAInvalidCiphertextLength(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	if _, err := DecryptRSA(key, make([]byte, key.Size()-1), RSAModePKCS1v15); err == nil {
+		t.Fatal("expected an error for a ciphertext length that is not a multiple of the block size")
+	}
+}
+
+func TestSealOpenRSAAES(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+	sealed, err := SealRSAAES(&key.PublicKey, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := OpenRSAAES(key, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatal("opened plaintext does not match the original")
+	}
+}
+
+func TestOpenRSAAESTamperedCiphertext(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	sealed, err := SealRSAAES(&key.PublicKey, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)-1] ^= 0xff
+	if _, err := OpenRSAAES(key, sealed); err == nil {
+		t.Fatal("expected an error for a tampered ciphertext")
+	}
+}
+
+func TestOpenRSAAESInvalidMagic(t *testing.T) {
+	key := testRSAEnvelopeKey(t)
+	sealed, err := SealRSAAES(&key.PublicKey, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[0] ^= 0xff
+	if _, err := OpenRSAAES(key, sealed); err == nil {
+		t.Fatal("expected an error for an invalid magic")
+	}
+}
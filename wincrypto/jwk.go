@@ -0,0 +1,155 @@
+package wincrypto
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// reference:
+// https://datatracker.ietf.org/doc/html/rfc7517
+
+// rsaJWK is the subset of RFC 7517/7518 fields needed to represent an RSA key.
+type rsaJWK struct {
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	DP  string `json:"dp,omitempty"`
+	DQ  string `json:"dq,omitempty"`
+	QI  string `json:"qi,omitempty"`
+}
+
+// ParseRSAPublicKeyJWK is used to load rsa public key from a JWK document.
+func ParseRSAPublicKeyJWK(data []byte) (*rsa.PublicKey, error) {
+	jwk := new(rsaJWK)
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, err
+	}
+	if jwk.Kty != "RSA" {
+		return nil, errors.New("jwk key type is not RSA")
+	}
+	n, err := decodeJWKBigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeJWKBigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// ParseRSAPrivateKeyJWK is used to load rsa private key from a JWK document.
+// If dp, dq and qi are absent, they are recomputed with Precompute.
+func ParseRSAPrivateKeyJWK(data []byte) (*rsa.PrivateKey, error) {
+	jwk := new(rsaJWK)
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, err
+	}
+	if jwk.Kty != "RSA" {
+		return nil, errors.New("jwk key type is not RSA")
+	}
+	if jwk.D == "" || jwk.P == "" || jwk.Q == "" {
+		return nil, errors.New("jwk does not contain a rsa private key")
+	}
+	n, err := decodeJWKBigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeJWKBigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeJWKBigInt(jwk.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeJWKBigInt(jwk.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeJWKBigInt(jwk.Q)
+	if err != nil {
+		return nil, err
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	if jwk.DP != "" && jwk.DQ != "" && jwk.QI != "" {
+		dp, err := decodeJWKBigInt(jwk.DP)
+		if err != nil {
+			return nil, err
+		}
+		dq, err := decodeJWKBigInt(jwk.DQ)
+		if err != nil {
+			return nil, err
+		}
+		qi, err := decodeJWKBigInt(jwk.QI)
+		if err != nil {
+			return nil, err
+		}
+		key.Precomputed = rsa.PrecomputedValues{
+			Dp:   dp,
+			Dq:   dq,
+			Qinv: qi,
+		}
+	} else {
+		key.Precompute()
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ExportRSAPublicKeyJWK is used to export rsa public key as a JWK document.
+func ExportRSAPublicKeyJWK(key *rsa.PublicKey) ([]byte, error) {
+	jwk := &rsaJWK{
+		Kty: "RSA",
+		N:   encodeJWKBigInt(key.N),
+		E:   encodeJWKBigInt(big.NewInt(int64(key.E))),
+	}
+	return json.Marshal(jwk)
+}
+
+// ExportRSAPrivateKeyJWK is used to export rsa private key as a JWK document.
+func ExportRSAPrivateKeyJWK(key *rsa.PrivateKey) ([]byte, error) {
+	if len(key.Primes) != 2 {
+		return nil, errors.New("jwk only supports rsa keys with two primes")
+	}
+	key.Precompute()
+	jwk := &rsaJWK{
+		Kty: "RSA",
+		N:   encodeJWKBigInt(key.N),
+		E:   encodeJWKBigInt(big.NewInt(int64(key.E))),
+		D:   encodeJWKBigInt(key.D),
+		P:   encodeJWKBigInt(key.Primes[0]),
+		Q:   encodeJWKBigInt(key.Primes[1]),
+		DP:  encodeJWKBigInt(key.Precomputed.Dp),
+		DQ:  encodeJWKBigInt(key.Precomputed.Dq),
+		QI:  encodeJWKBigInt(key.Precomputed.Qinv),
+	}
+	return json.Marshal(jwk)
+}
+
+func decodeJWKBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("jwk is missing a required field")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func encodeJWKBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
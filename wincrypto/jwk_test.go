@@ -0,0 +1,94 @@
+package wincrypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+)
+
+func TestExportImportRSAPublicKeyJWK(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportRSAPublicKeyJWK(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParseRSAPublicKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("parsed public key does not match the original")
+	}
+}
+
+func TestExportImportRSAPrivateKeyJWK(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportRSAPrivateKeyJWK(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParseRSAPrivateKeyJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("parsed private key does not match the original")
+	}
+}
+
+func TestParseRSAPrivateKeyJWKPrecomputeFallback(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportRSAPrivateKeyJWK(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk := new(rsaJWK)
+	if err := json.Unmarshal(data, jwk); err != nil {
+		t.Fatal(err)
+	}
+	// strip the CRT parameters, forcing ParseRSAPrivateKeyJWK to recompute
+	// them with Precompute instead of reading dp/dq/qi.
+	jwk.DP, jwk.DQ, jwk.QI = "", "", ""
+	stripped, err := json.Marshal(jwk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParseRSAPrivateKeyJWK(stripped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("parsed private key does not match the original after precompute fallback")
+	}
+}
+
+func TestParseRSAPublicKeyJWKNonRSA(t *testing.T) {
+	data := []byte(`{"kty":"EC","crv":"P-256","x":"","y":""}`)
+	if _, err := ParseRSAPublicKeyJWK(data); err == nil {
+		t.Fatal("expected an error for a non-RSA JWK")
+	}
+}
+
+func TestParseRSAPrivateKeyJWKMissingPrivateFields(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportRSAPublicKeyJWK(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRSAPrivateKeyJWK(data); err == nil {
+		t.Fatal("expected an error when private fields are absent")
+	}
+}
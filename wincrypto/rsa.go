@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"encoding/pem"
 	"errors"
+	"io"
 	"math/big"
 )
 
@@ -69,22 +70,93 @@ const (
 	RSAKeyUsageKEYX = 2
 )
 
-// ParseRSAPublicKeyPEM is used to load rsa public key from PEM block.
+// ParseRSAPublicKeyPEM is used to load rsa public key from PEM data. It
+// accepts a PUBLIC KEY block, or a CERTIFICATE block whose embedded key is
+// RSA, and skips over unrelated blocks in a multi-block bundle.
 func ParseRSAPublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
-	der, _ := pem.Decode(data)
-	if der == nil {
-		return nil, errors.New("failed to decode PEM data")
+	for {
+		var der *pem.Block
+		der, data = pem.Decode(data)
+		if der == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		if der.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(der.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			pub, ok := cert.PublicKey.(*rsa.PublicKey)
+			if !ok {
+				return nil, errors.New("certificate does not contain a rsa public key")
+			}
+			return pub, nil
+		}
+		key, err := ParseRSAPublicKey(der.Bytes)
+		if err == nil {
+			return key, nil
+		}
+		if len(data) == 0 {
+			return nil, err
+		}
 	}
-	return ParseRSAPublicKey(der.Bytes)
 }
 
-// ParseRSAPrivateKeyPEM is used to load rsa private key from PEM block.
+// ParseRSAPrivateKeyPEM is used to load rsa private key from PEM data,
+// skipping over unrelated blocks in a multi-block bundle. It does not
+// support password-protected PEM blocks, use ParseRSAPrivateKeyPEMWithPassword
+// for those.
 func ParseRSAPrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
-	der, _ := pem.Decode(data)
-	if der == nil {
-		return nil, errors.New("failed to decode PEM data")
+	for {
+		var der *pem.Block
+		der, data = pem.Decode(data)
+		if der == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		if x509.IsEncryptedPEMBlock(der) { //nolint:staticcheck
+			if len(data) == 0 {
+				return nil, errors.New("PEM block is encrypted, use ParseRSAPrivateKeyPEMWithPassword")
+			}
+			continue
+		}
+		key, err := ParseRSAPrivateKey(der.Bytes)
+		if err == nil {
+			return key, nil
+		}
+		if len(data) == 0 {
+			return nil, err
+		}
+	}
+}
+
+// ParseRSAPrivateKeyPEMWithPassword is used to load rsa private key from a
+// password-protected PEM block (as produced by the legacy "ENCRYPTED" PEM
+// header), skipping over unrelated blocks in a multi-block bundle.
+func ParseRSAPrivateKeyPEMWithPassword(data, password []byte) (*rsa.PrivateKey, error) {
+	for {
+		var der *pem.Block
+		der, data = pem.Decode(data)
+		if der == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		derBytes := der.Bytes
+		if x509.IsEncryptedPEMBlock(der) { //nolint:staticcheck
+			decrypted, err := x509.DecryptPEMBlock(der, password) //nolint:staticcheck
+			if err != nil {
+				if len(data) == 0 {
+					return nil, err
+				}
+				continue
+			}
+			derBytes = decrypted
+		}
+		key, err := ParseRSAPrivateKey(derBytes)
+		if err == nil {
+			return key, nil
+		}
+		if len(data) == 0 {
+			return nil, err
+		}
 	}
-	return ParseRSAPrivateKey(der.Bytes)
 }
 
 // ParseRSAPublicKey is used to load rsa public key from ASN.1 DER data.
@@ -200,6 +272,155 @@ func ExportRSAPrivateKeyBlob(key *rsa.PrivateKey, usage int) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// ImportRSAPublicKeyBlob is used to import rsa public key from PublicKeyBlob.
+// It returns the parsed key, the RSAKeyUsage* associated with the blob, and
+// an error if the blob is malformed.
+func ImportRSAPublicKeyBlob(blob []byte) (*rsa.PublicKey, int, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readBlobHeader(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.bType != publicKeyBlob {
+		return nil, 0, errors.New("invalid public key blob type")
+	}
+	usage, err := parseKeyUsage(header.aiKeyAlg)
+	if err != nil {
+		return nil, 0, err
+	}
+	pub, err := readRSAPubKey(reader, magicRSA1)
+	if err != nil {
+		return nil, 0, err
+	}
+	modulus := make([]byte, pub.bitLen/8)
+	if _, err := io.ReadFull(reader, modulus); err != nil {
+		return nil, 0, errors.New("failed to read modulus")
+	}
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(reverseBytes(modulus)),
+		E: int(pub.pubExp),
+	}
+	return key, usage, nil
+}
+
+// ImportRSAPrivateKeyBlob is used to import rsa private key from PrivateKeyBlob.
+// It returns the parsed key, the RSAKeyUsage* associated with the blob, and
+// an error if the blob is malformed.
+func ImportRSAPrivateKeyBlob(blob []byte) (*rsa.PrivateKey, int, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readBlobHeader(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.bType != privateKeyBlob {
+		return nil, 0, errors.New("invalid private key blob type")
+	}
+	usage, err := parseKeyUsage(header.aiKeyAlg)
+	if err != nil {
+		return nil, 0, err
+	}
+	pub, err := readRSAPubKey(reader, magicRSA2)
+	if err != nil {
+		return nil, 0, err
+	}
+	keyLen := int(pub.bitLen / 8)
+	readBigInt := func(len int) (*big.Int, error) {
+		buf := make([]byte, len)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, errors.New("failed to read private key field")
+		}
+		return new(big.Int).SetBytes(reverseBytes(buf)), nil
+	}
+	modulus, err := readBigInt(keyLen)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, err := readBigInt(keyLen / 2)
+	if err != nil {
+		return nil, 0, err
+	}
+	q, err := readBigInt(keyLen / 2)
+	if err != nil {
+		return nil, 0, err
+	}
+	// exponent1, exponent2 and coefficient are redundant with D and can
+	// be recomputed by Precompute, skip them but still advance the reader.
+	if _, err := readBigInt(keyLen / 2); err != nil { // exponent1
+		return nil, 0, err
+	}
+	if _, err := readBigInt(keyLen / 2); err != nil { // exponent2
+		return nil, 0, err
+	}
+	if _, err := readBigInt(keyLen / 2); err != nil { // coefficient
+		return nil, 0, err
+	}
+	d, err := readBigInt(keyLen)
+	if err != nil {
+		return nil, 0, err
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{
+			N: modulus,
+			E: int(pub.pubExp),
+		},
+		D:      d,
+		Primes: []*big.Int{p, q},
+	}
+	key.Precompute()
+	if err := key.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return key, usage, nil
+}
+
+func readBlobHeader(reader *bytes.Reader) (*blobHeader, error) {
+	header := new(blobHeader)
+	if err := binary.Read(reader, binary.LittleEndian, &header.bType); err != nil {
+		return nil, errors.New("failed to read blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.bVersion); err != nil {
+		return nil, errors.New("failed to read blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.reserved); err != nil {
+		return nil, errors.New("failed to read blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.aiKeyAlg); err != nil {
+		return nil, errors.New("failed to read blob header")
+	}
+	if header.bVersion != curBlobVersion {
+		return nil, errors.New("invalid blob version")
+	}
+	return header, nil
+}
+
+func parseKeyUsage(aiKeyAlg uint32) (int, error) {
+	switch aiKeyAlg {
+	case cAlgRSASign:
+		return RSAKeyUsageSIGN, nil
+	case cAlgRSAKeyX:
+		return RSAKeyUsageKEYX, nil
+	default:
+		return 0, errors.New("invalid rsa key usage")
+	}
+}
+
+func readRSAPubKey(reader *bytes.Reader, magic uint32) (*rsaPubKey, error) {
+	pub := new(rsaPubKey)
+	if err := binary.Read(reader, binary.LittleEndian, &pub.magic); err != nil {
+		return nil, errors.New("failed to read rsa public key header")
+	}
+	if pub.magic != magic {
+		return nil, errors.New("invalid rsa public key magic")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &pub.bitLen); err != nil {
+		return nil, errors.New("failed to read rsa public key header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &pub.pubExp); err != nil {
+		return nil, errors.New("failed to read rsa public key header")
+	}
+	return pub, nil
+}
+
 func reverseBytes(b []byte) []byte {
 	n := len(b)
 	r := make([]byte, n)
@@ -0,0 +1,244 @@
+package wincrypto
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// reference:
+// https://learn.microsoft.com/en-us/windows/win32/api/bcrypt/ns-bcrypt-bcrypt_rsakey_blob
+
+// CNG RSA key blob magic numbers, see BCRYPT_RSAPUBLIC_MAGIC and friends.
+const (
+	magicRSAPublic      = 0x31415352 // RSA1
+	magicRSAPrivate     = 0x32415352 // RSA2
+	magicRSAFullPrivate = 0x33415352 // RSA3
+)
+
+// about CNG RSA private key export mode.
+const (
+	RSAPrivateCNG     = 1
+	RSAFullPrivateCNG = 2
+)
+
+// rsaKeyBlobCNG is the BCRYPT_RSAKEY_BLOB header. The header itself is
+// native byte order (little endian), but unlike the legacy CryptoAPI blobs
+// the key material that follows it is big endian.
+type rsaKeyBlobCNG struct {
+	magic     uint32
+	bitLength uint32
+	cbPubExp  uint32
+	cbModulus uint32
+	cbPrime1  uint32
+	cbPrime2  uint32
+}
+
+// ExportRSAPublicKeyBlobCNG is used to export rsa public key with the CNG
+// BCRYPT_RSAPUBLIC_BLOB format consumed by BCryptImportKeyPair.
+func ExportRSAPublicKeyBlobCNG(key *rsa.PublicKey) ([]byte, error) {
+	exp := big.NewInt(int64(key.E)).Bytes()
+	modulus := key.N.Bytes()
+	buffer := bytes.NewBuffer(make([]byte, 0, 24+len(exp)+len(modulus)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(magicRSAPublic))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(key.Size()*8))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(exp)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(modulus)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(0))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(0))
+	buffer.Write(exp)
+	buffer.Write(modulus)
+	return buffer.Bytes(), nil
+}
+
+// ExportRSAPrivateKeyBlobCNG is used to export rsa private key with the CNG
+// BCRYPT_RSAPRIVATE_BLOB or BCRYPT_RSAFULLPRIVATE_BLOB format, selected by
+// mode (RSAPrivateCNG or RSAFullPrivateCNG), consumed by BCryptImportKeyPair.
+func ExportRSAPrivateKeyBlobCNG(key *rsa.PrivateKey, mode int) ([]byte, error) {
+	switch mode {
+	case RSAPrivateCNG, RSAFullPrivateCNG:
+	default:
+		return nil, errors.New("invalid rsa cng private key mode")
+	}
+	exp := big.NewInt(int64(key.E)).Bytes()
+	modulus := key.N.Bytes()
+	keyLen := key.Size()
+	p := key.Primes[0]
+	q := key.Primes[1]
+	prime1 := make([]byte, keyLen/2)
+	prime1 = p.FillBytes(prime1)
+	prime2 := make([]byte, keyLen/2)
+	prime2 = q.FillBytes(prime2)
+	magic := magicRSAPrivate
+	if mode == RSAFullPrivateCNG {
+		magic = magicRSAFullPrivate
+	}
+	buffer := bytes.NewBuffer(make([]byte, 0, keyLen*4))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(magic))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(keyLen*8))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(exp)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(modulus)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(prime1)))
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(prime2)))
+	buffer.Write(exp)
+	buffer.Write(modulus)
+	buffer.Write(prime1)
+	buffer.Write(prime2)
+	if mode == RSAPrivateCNG {
+		return buffer.Bytes(), nil
+	}
+	// exponent1 = d mod (P-1)
+	pMinus1 := new(big.Int).Sub(p, big.NewInt(1))
+	exponent1 := new(big.Int).Mod(key.D, pMinus1)
+	buf := make([]byte, keyLen/2)
+	buffer.Write(exponent1.FillBytes(buf))
+	// exponent2 = d mod (Q-1)
+	qMinus1 := new(big.Int).Sub(q, big.NewInt(1))
+	exponent2 := new(big.Int).Mod(key.D, qMinus1)
+	buf = make([]byte, keyLen/2)
+	buffer.Write(exponent2.FillBytes(buf))
+	// coefficient = Q^-1 mod P
+	coefficient := new(big.Int).ModInverse(q, p)
+	buf = make([]byte, keyLen/2)
+	buffer.Write(coefficient.FillBytes(buf))
+	// private exponent D
+	buf = make([]byte, keyLen)
+	buffer.Write(key.D.FillBytes(buf))
+	return buffer.Bytes(), nil
+}
+
+// ImportRSAPublicKeyBlobCNG is used to import rsa public key from the CNG
+// BCRYPT_RSAPUBLIC_BLOB format.
+func ImportRSAPublicKeyBlobCNG(blob []byte) (*rsa.PublicKey, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readRSAKeyBlobCNGHeader(reader, magicRSAPublic)
+	if err != nil {
+		return nil, err
+	}
+	exp := make([]byte, header.cbPubExp)
+	if _, err := io.ReadFull(reader, exp); err != nil {
+		return nil, errors.New("failed to read public exponent")
+	}
+	modulus := make([]byte, header.cbModulus)
+	if _, err := io.ReadFull(reader, modulus); err != nil {
+		return nil, errors.New("failed to read modulus")
+	}
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exp).Int64()),
+	}
+	return key, nil
+}
+
+// ImportRSAPrivateKeyBlobCNG is used to import rsa private key from the CNG
+// BCRYPT_RSAPRIVATE_BLOB or BCRYPT_RSAFULLPRIVATE_BLOB format.
+func ImportRSAPrivateKeyBlobCNG(blob []byte) (*rsa.PrivateKey, error) {
+	reader := bytes.NewReader(blob)
+	header, err := readRSAKeyBlobCNGHeader(reader, magicRSAPrivate, magicRSAFullPrivate)
+	if err != nil {
+		return nil, err
+	}
+	exp := make([]byte, header.cbPubExp)
+	if _, err := io.ReadFull(reader, exp); err != nil {
+		return nil, errors.New("failed to read public exponent")
+	}
+	modulus := make([]byte, header.cbModulus)
+	if _, err := io.ReadFull(reader, modulus); err != nil {
+		return nil, errors.New("failed to read modulus")
+	}
+	prime1 := make([]byte, header.cbPrime1)
+	if _, err := io.ReadFull(reader, prime1); err != nil {
+		return nil, errors.New("failed to read prime1")
+	}
+	prime2 := make([]byte, header.cbPrime2)
+	if _, err := io.ReadFull(reader, prime2); err != nil {
+		return nil, errors.New("failed to read prime2")
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exp).Int64()),
+		},
+		Primes: []*big.Int{
+			new(big.Int).SetBytes(prime1),
+			new(big.Int).SetBytes(prime2),
+		},
+	}
+	if header.magic == magicRSAFullPrivate {
+		// skip exponent1, exponent2 and coefficient, they are redundant
+		// with D and recomputed below.
+		skip := make([]byte, 2*header.cbPrime1+header.cbPrime2)
+		if _, err := io.ReadFull(reader, skip); err != nil {
+			return nil, errors.New("failed to read crt values")
+		}
+		d := make([]byte, header.cbModulus)
+		if _, err := io.ReadFull(reader, d); err != nil {
+			return nil, errors.New("failed to read private exponent")
+		}
+		key.D = new(big.Int).SetBytes(d)
+	} else {
+		d, err := deriveRSAPrivateExponent(key.N, key.E, key.Primes[0], key.Primes[1])
+		if err != nil {
+			return nil, err
+		}
+		key.D = d
+	}
+	key.Precompute()
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func readRSAKeyBlobCNGHeader(reader *bytes.Reader, magics ...uint32) (*rsaKeyBlobCNG, error) {
+	header := new(rsaKeyBlobCNG)
+	if err := binary.Read(reader, binary.LittleEndian, &header.magic); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	valid := false
+	for _, magic := range magics {
+		if header.magic == magic {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, errors.New("invalid cng rsa key blob magic")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.bitLength); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.cbPubExp); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.cbModulus); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.cbPrime1); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header.cbPrime2); err != nil {
+		return nil, errors.New("failed to read cng key blob header")
+	}
+	return header, nil
+}
+
+// deriveRSAPrivateExponent recovers D from N, E, P and Q for the CNG
+// RSAPRIVATE blob, which does not carry the private exponent. D is computed
+// as E^-1 mod lcm(P-1, Q-1), the same form crypto/rsa.GenerateKey produces.
+func deriveRSAPrivateExponent(n *big.Int, e int, p, q *big.Int) (*big.Int, error) {
+	_ = n
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	phi := new(big.Int).Mul(pMinus1, qMinus1)
+	lambda := new(big.Int).Div(phi, new(big.Int).GCD(nil, nil, pMinus1, qMinus1))
+	d := new(big.Int).ModInverse(big.NewInt(int64(e)), lambda)
+	if d == nil {
+		return nil, errors.New("failed to derive private exponent")
+	}
+	return d, nil
+}
@@ -0,0 +1,71 @@
+package wincrypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestExportImportRSAPublicKeyBlobCNG(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPublicKeyBlobCNG(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ImportRSAPublicKeyBlobCNG(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("imported public key does not match the original")
+	}
+}
+
+func TestExportImportRSAPrivateKeyBlobCNG(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPrivateKeyBlobCNG(key, RSAPrivateCNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ImportRSAPrivateKeyBlobCNG(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("imported private key does not match the original")
+	}
+}
+
+func TestExportImportRSAFullPrivateKeyBlobCNG(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPrivateKeyBlobCNG(key, RSAFullPrivateCNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ImportRSAPrivateKeyBlobCNG(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("imported private key does not match the original")
+	}
+}
+
+func TestExportRSAPrivateKeyBlobCNGInvalidMode(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportRSAPrivateKeyBlobCNG(key, 0); err == nil {
+		t.Fatal("expected an error for an invalid mode")
+	}
+}
@@ -0,0 +1,207 @@
+package wincrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testSelfSignedCert(t *testing.T, pub, signer interface{}, priv interface{}) []byte {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "wincrypto test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseRSAPublicKeyPEMFromCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := testSelfSignedCert(t, &key.PublicKey, key, key)
+	pub, err := ParseRSAPublicKeyPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("parsed public key does not match the certificate key")
+	}
+}
+
+func TestParseRSAPublicKeyPEMFromNonRSACertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := testSelfSignedCert(t, &key.PublicKey, key, key)
+	if _, err := ParseRSAPublicKeyPEM(certPEM); err == nil {
+		t.Fatal("expected an error for a certificate with a non-RSA public key")
+	}
+}
+
+func TestParseRSAPrivateKeyPEMWithPassword(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", der, []byte("hunter2"), x509.PEMCipherAES256) //nolint:staticcheck
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPEM := pem.EncodeToMemory(block)
+
+	priv, err := ParseRSAPrivateKeyPEMWithPassword(encPEM, []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("parsed private key does not match the original")
+	}
+
+	if _, err := ParseRSAPrivateKeyPEMWithPassword(encPEM, []byte("wrong password")); err == nil {
+		t.Fatal("expected an error for the wrong password")
+	}
+
+	if _, err := ParseRSAPrivateKeyPEM(encPEM); err == nil {
+		t.Fatal("expected an error when parsing an encrypted PEM without a password")
+	}
+}
+
+func TestParseRSAPublicKeyPEMMultiBlockBundle(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := testSelfSignedCert(t, &key.PublicKey, key, key)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&otherKey.PublicKey),
+	})
+	// the certificate block comes first, ParseRSAPublicKeyPEM should return
+	// its embedded key rather than falling through to the second block.
+	bundle := append(append([]byte{}, certPEM...), pubPEM...)
+	pub, err := ParseRSAPublicKeyPEM(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("expected the key embedded in the first certificate block")
+	}
+}
+
+func TestExportImportRSAPublicKeyBlob(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPublicKeyBlob(&key.PublicKey, RSAKeyUsageKEYX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, usage, err := ImportRSAPublicKeyBlob(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != RSAKeyUsageKEYX {
+		t.Fatalf("unexpected key usage: %d", usage)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("imported public key does not match the original")
+	}
+}
+
+func TestExportImportRSAPrivateKeyBlob(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPrivateKeyBlob(key, RSAKeyUsageSIGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, usage, err := ImportRSAPrivateKeyBlob(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != RSAKeyUsageSIGN {
+		t.Fatalf("unexpected key usage: %d", usage)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("imported private key does not match the original")
+	}
+}
+
+func TestImportRSAPublicKeyBlobInvalidType(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPrivateKeyBlob(key, RSAKeyUsageKEYX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ImportRSAPublicKeyBlob(blob); err == nil {
+		t.Fatal("expected an error when importing a private key blob as a public key blob")
+	}
+}
+
+func TestImportRSAPrivateKeyBlobMalformedHeader(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := ExportRSAPrivateKeyBlob(key, RSAKeyUsageSIGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ImportRSAPrivateKeyBlob(blob[:len(blob)-1]); err == nil {
+		t.Fatal("expected an error for a truncated blob")
+	}
+	corrupted := append([]byte{}, blob...)
+	corrupted[1] = 0xff // bVersion
+	if _, _, err := ImportRSAPrivateKeyBlob(corrupted); err == nil {
+		t.Fatal("expected an error for an invalid blob version")
+	}
+}
+
+func TestParseRSAPrivateKeyPEMMultiBlockBundle(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := testSelfSignedCert(t, &key.PublicKey, key, key)
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	// the certificate block carries no private key, ParseRSAPrivateKeyPEM
+	// must skip it and fall through to the key block.
+	bundle := append(append([]byte{}, certPEM...), keyPEM...)
+	priv, err := ParseRSAPrivateKeyPEM(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Fatal("expected the key from the second PEM block")
+	}
+}